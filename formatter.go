@@ -0,0 +1,55 @@
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Formatter renders a Message for a given Instance into the string that
+// gets written out. Instance.Formatter defaults to a TextFormatter built
+// from Instance.Format; JSONFormatter is the structured alternative.
+type Formatter interface {
+	Format(i *Instance, msg *Message) string
+}
+
+// TextFormatter is the original expand-placeholder behaviour: every
+// Expand* key found in msg.values is substituted into the template.
+type TextFormatter string
+
+func (f TextFormatter) Format(i *Instance, msg *Message) string {
+	out := string(f)
+	for expand, value := range msg.values {
+		out = strings.Replace(out, expand, value, -1)
+	}
+
+	return out
+}
+
+// JSONFormatter renders a Message as a single JSON object per line,
+// including every field attached via With.
+type JSONFormatter struct{}
+
+func (JSONFormatter) Format(i *Instance, msg *Message) string {
+	entry := make(map[string]interface{}, len(msg.Fields)+5)
+	entry["level"] = msg.values[ExpandMessageLevel]
+	entry["time"] = msg.values[ExpandTime]
+	entry["subject"] = msg.values[ExpandSubject]
+	entry["message"] = msg.values[ExpandMessage]
+
+	if i.needsRuntime {
+		entry["function"] = msg.values[ExpandFunctionName]
+		entry["line"] = msg.values[ExpandLineNumber]
+	}
+
+	for k, v := range msg.Fields {
+		entry[k] = v
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Sprintf(`{"level":%q,"message":%q}`, msg.Level.String(), err.Error())
+	}
+
+	return string(b)
+}
@@ -0,0 +1,97 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONFormatterFields(t *testing.T) {
+	i := &Instance{Name: "json-test", needsRuntime: true}
+	msg := &Message{
+		Level: Info,
+		values: map[string]string{
+			ExpandMessageLevel: "INFO",
+			ExpandTime:         "sometime",
+			ExpandSubject:      "svc",
+			ExpandMessage:      "hello",
+			ExpandFunctionName: "pkg.Fn",
+			ExpandLineNumber:   "42",
+		},
+		Fields: map[string]interface{}{"user_id": 42},
+	}
+
+	out := JSONFormatter{}.Format(i, msg)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("JSONFormatter produced invalid JSON: %v, out=%q", err, out)
+	}
+
+	want := map[string]interface{}{
+		"level":    "INFO",
+		"time":     "sometime",
+		"subject":  "svc",
+		"message":  "hello",
+		"function": "pkg.Fn",
+		"line":     "42",
+		"user_id":  float64(42),
+	}
+	for k, v := range want {
+		if decoded[k] != v {
+			t.Errorf("decoded[%q] = %v, want %v", k, decoded[k], v)
+		}
+	}
+}
+
+func TestJSONFormatterOmitsRuntimeWhenNotNeeded(t *testing.T) {
+	i := &Instance{Name: "json-test"}
+	msg := &Message{values: map[string]string{
+		ExpandMessageLevel: "INFO",
+		ExpandTime:         "sometime",
+		ExpandSubject:      "svc",
+		ExpandMessage:      "hello",
+	}}
+
+	out := JSONFormatter{}.Format(i, msg)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("JSONFormatter produced invalid JSON: %v, out=%q", err, out)
+	}
+
+	if _, ok := decoded["function"]; ok {
+		t.Errorf("function present despite needsRuntime == false: %v", decoded)
+	}
+	if _, ok := decoded["line"]; ok {
+		t.Errorf("line present despite needsRuntime == false: %v", decoded)
+	}
+}
+
+// TestWithLoggerEndToEndJSON exercises With(...).With(...) feeding a
+// JSONFormatter instance through the real dispatcher, including a message
+// containing a literal '%' to guard against the Printf-as-template bug.
+func TestWithLoggerEndToEndJSON(t *testing.T) {
+	Reset()
+
+	buf := &bytes.Buffer{}
+	AddLoggerInstanceWithFormatter("with-test", "[LEVEL] SUBJECT, MESSAGE", buf, JSONFormatter{})
+
+	With("component", "auth").With("user_id", 42).Info("svc", "disk at %d%% capacity", 87)
+	Flush()
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v, out=%q", err, buf.String())
+	}
+
+	if decoded["component"] != "auth" {
+		t.Errorf("component = %v, want auth", decoded["component"])
+	}
+	if decoded["user_id"] != float64(42) {
+		t.Errorf("user_id = %v, want 42", decoded["user_id"])
+	}
+	if decoded["message"] != "disk at 87% capacity" {
+		t.Errorf("message = %v, want %q", decoded["message"], "disk at 87% capacity")
+	}
+}
@@ -58,21 +58,53 @@ func (ll LogLevel) String() string {
 type Instance struct {
 	Name         string
 	Format       string
+	Formatter    Formatter
+	LogLevel     LogLevel
 	output       io.Writer
 	tags         []string
 	log          *log.Logger
 	needsRuntime bool
+	middleware   []Middleware
 
 	UpdateOutput        func()
 	UpdateOutputTrigger time.Ticker
 }
 
+// Use appends mw to the instance's middleware chain, run in order inside
+// the dispatcher goroutine before formatting. A Middleware returning false
+// drops the message for this instance only. Put mutating middleware like
+// RedactKeys/RedactRegexp before dropping middleware like Sample, so a
+// message that does get through has already been scrubbed. Call it on the
+// *Instance returned by AddLoggerInstance (or one of its variants) to
+// attach middleware after registration.
+func (i *Instance) Use(mw ...Middleware) {
+	i.middleware = append(i.middleware, mw...)
+}
+
+// SetLogLevel changes the instance's own threshold, on top of the global
+// level. The change is applied by the dispatcher goroutine so it can never
+// race with the per-instance filtering done in init().
+func (i *Instance) SetLogLevel(level LogLevel) {
+	reply := make(chan LogLevel)
+	InstanceLevel <- levelRequest{instance: i, level: level, reply: reply}
+	<-reply
+}
+
+// GetLogLevel returns the instance's current threshold. It is read through
+// the dispatcher goroutine so it can never race with SetLogLevel.
+func (i *Instance) GetLogLevel() LogLevel {
+	reply := make(chan LogLevel)
+	InstanceLevel <- levelRequest{instance: i, get: true, reply: reply}
+	return <-reply
+}
+
 func newInstance(name, format string, output io.Writer, tags ...string) *Instance {
 	i := &Instance{
-		Name:   name,
-		Format: format,
-		output: output,
-		tags:   tags,
+		Name:      name,
+		Format:    format,
+		Formatter: TextFormatter(format),
+		output:    output,
+		tags:      tags,
 	}
 
 	return i.checkForRuntime()
@@ -86,22 +118,44 @@ func (i *Instance) checkForRuntime() *Instance {
 	return i
 }
 
-func (i *Instance) expand(format string, msg *Message) string {
-	for expand, value := range msg.values {
-		format = strings.Replace(format, expand, value, -1)
-	}
-
-	return format
-}
-
 func (i *Instance) initialize() *Instance {
 	//i.log = log.New(os.Stdout, "", log.LstdFlags)
 	i.log = log.New(i.output, "", 0)
 	return i
 }
 
-func AddLoggerInstance(name, format string, output io.Writer, tags ...string) {
-	RegisterLogger <- newInstance(name, format, output, tags...).initialize()
+// AddLoggerInstance registers a new Instance and returns it, so callers can
+// still reach it afterwards, e.g. to call SetLogLevel or Use.
+func AddLoggerInstance(name, format string, output io.Writer, tags ...string) *Instance {
+	instance := newInstance(name, format, output, tags...).initialize()
+	RegisterLogger <- instance
+	return instance
+}
+
+// AddLoggerInstanceWithLevel is identical to AddLoggerInstance, but gives the
+// new instance its own threshold instead of inheriting the global level for
+// everything. A message still has to clear the global level first; this
+// level is applied on top of that, e.g. a stderr instance can stay at
+// Warning while a file instance is set to Debug.
+func AddLoggerInstanceWithLevel(name, format string, output io.Writer, level LogLevel, tags ...string) *Instance {
+	instance := newInstance(name, format, output, tags...).initialize()
+	instance.LogLevel = level
+	RegisterLogger <- instance
+	return instance
+}
+
+// AddLoggerInstanceWithFormatter is identical to AddLoggerInstance, but lets
+// the caller replace the default TextFormatter with, e.g., JSONFormatter.
+// format is still used to set needsRuntime even when formatter ignores
+// format entirely (FUNCTION/LINE are always captured by Log, but e.g.
+// JSONFormatter only includes "function"/"line" when format itself
+// contains the FUNCTION or LINE placeholder, so pass e.g. "FUNCTION LINE"
+// to turn that on for a JSONFormatter instance.
+func AddLoggerInstanceWithFormatter(name, format string, output io.Writer, formatter Formatter, tags ...string) *Instance {
+	instance := newInstance(name, format, output, tags...).initialize()
+	instance.Formatter = formatter
+	RegisterLogger <- instance
+	return instance
 }
 
 type Message struct {
@@ -111,6 +165,22 @@ type Message struct {
 	values   map[string]string
 	Format   string
 	Elements []interface{}
+	Fields   map[string]interface{}
+}
+
+// levelRequest gets or sets a single Instance's LogLevel through the
+// dispatcher goroutine. instance is the stable *Instance pointer returned
+// by AddLoggerInstance, not a name lookup into gologgers: RegisterLogger
+// and InstanceLevel are two independent channels serviced by the same
+// select, so a request right after AddLoggerInstance can otherwise reach
+// the dispatcher before the instance has actually been appended. get
+// distinguishes a query from a change; reply always receives the
+// instance's level after the request is handled.
+type levelRequest struct {
+	instance *Instance
+	level    LogLevel
+	get      bool
+	reply    chan LogLevel
 }
 
 var (
@@ -118,7 +188,13 @@ var (
 	Sink chan Message
 	// NewLevel is channel that can change the log level
 	NewLevel chan LogLevel
-	level    LogLevel = Info
+	// InstanceLevel gets or sets the LogLevel of a single Instance
+	InstanceLevel chan levelRequest
+	// flushC and resetC carry Flush/Reset requests into the dispatcher
+	// goroutine; the reply channel is closed once handled.
+	flushC chan chan struct{}
+	resetC chan chan struct{}
+	level  LogLevel = Info
 
 	// By default there is one logging instance, and it logs to stdout.
 	gologgers []*Instance = []*Instance{newInstance("default", "[LEVEL] SUBJECT, MESSAGE", os.Stdout).initialize()}
@@ -145,7 +221,7 @@ func Log(level LogLevel, subject interface{}, format string, elements ...interfa
 		ExpandDuration:     time.Now().Sub(start).String(),
 	}
 
-	Sink <- Message{level, subject, nil, values, format, elements}
+	Sink <- Message{level, subject, nil, values, format, elements, nil}
 }
 
 func IsProduction(b bool) {
@@ -154,11 +230,128 @@ func IsProduction(b bool) {
 	}
 }
 
+// syncer is implemented by writers such as *os.File that can flush
+// buffered data to stable storage.
+type syncer interface {
+	Sync() error
+}
+
+// Flush blocks until every Message currently queued on Sink has been
+// dispatched and any Instance whose writer implements syncer has been
+// synced. Safe to call repeatedly and from tests.
+func Flush() {
+	reply := make(chan struct{})
+	flushC <- reply
+	<-reply
+}
+
+// Reset drains Sink like Flush, then clears gologgers and reinstalls the
+// default stdout instance and the default level. Safe to call repeatedly
+// and from tests that need a clean slate between cases.
+func Reset() {
+	reply := make(chan struct{})
+	resetC <- reply
+	<-reply
+}
+
+// dispatch runs the filtering/formatting steps for a single Message. It is
+// only ever called from the dispatcher goroutine in init().
+func dispatch(msg Message) {
+	if msg.Level < level {
+		return
+	}
+
+	var loggers []*Instance
+
+	for _, l := range gologgers {
+		// Per-instance threshold, on top of the global one above.
+		if msg.Level < l.LogLevel {
+			continue
+		}
+
+		// Verify tags, no tags means pass on everything. Right now, though.
+		if len(l.tags) > 0 {
+			if !matchTags(msg.Tags, l.tags) {
+				continue
+			}
+		}
+
+		loggers = append(loggers, l)
+	}
+
+	if len(loggers) == 0 {
+		return
+	}
+
+	msg.values[ExpandMessage] = fmt.Sprintf(msg.Format, msg.Elements...)
+	msg.values[ExpandSubject] = fmt.Sprintf("%v", msg.Subject)
+	msg.values[ExpandMessageLevel] = msg.Level.String()
+
+	for _, l := range loggers {
+		lmsg := msg
+		if len(l.middleware) > 0 {
+			lmsg.values = cloneStringMap(msg.values)
+			lmsg.Fields = cloneFields(msg.Fields)
+		}
+
+		dropped := false
+		for _, mw := range l.middleware {
+			if !mw(&lmsg) {
+				dropped = true
+				break
+			}
+		}
+		if dropped {
+			continue
+		}
+
+		l.log.Printf("%s", l.Formatter.Format(l, &lmsg))
+	}
+}
+
+func cloneStringMap(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+
+	return out
+}
+
+func cloneFields(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+
+	return out
+}
+
+// drainSink processes every Message already queued on Sink without
+// blocking for more. Only safe to call from the dispatcher goroutine.
+func drainSink() {
+	for {
+		select {
+		case msg := <-Sink:
+			dispatch(msg)
+		default:
+			return
+		}
+	}
+}
+
 func init() {
 	Sink = make(chan Message, 256)
 	RegisterLogger = make(chan *Instance, 1)
 	DeRegisterLogger = make(chan string, 1)
 	NewLevel = make(chan LogLevel)
+	InstanceLevel = make(chan levelRequest)
+	flushC = make(chan chan struct{})
+	resetC = make(chan chan struct{})
 
 	go func() {
 		for {
@@ -177,36 +370,26 @@ func init() {
 				gologgers = gologgers[:i]
 			case newLevel := <-NewLevel:
 				level = newLevel
-			case msg := <-Sink:
-				if msg.Level < level {
-					break
+			case req := <-InstanceLevel:
+				if !req.get {
+					req.instance.LogLevel = req.level
 				}
-
-				var loggers []*Instance
-
+				req.reply <- req.instance.LogLevel
+			case msg := <-Sink:
+				dispatch(msg)
+			case reply := <-flushC:
+				drainSink()
 				for _, l := range gologgers {
-					// Verify tags, no tags means pass on everything. Right now, though.
-					if len(l.tags) > 0 {
-						if !matchTags(msg.Tags, l.tags) {
-							continue
-						}
+					if s, ok := l.output.(syncer); ok {
+						s.Sync()
 					}
-
-					loggers = append(loggers, l)
-				}
-
-				if len(loggers) == 0 {
-					break
-				}
-
-				msg.values[ExpandMessage] = fmt.Sprintf(msg.Format, msg.Elements...)
-				msg.values[ExpandSubject] = fmt.Sprintf("%v", msg.Subject)
-				msg.values[ExpandMessageLevel] = msg.Level.String()
-
-				for _, l := range loggers {
-					format := l.expand(l.Format, &msg)
-					l.log.Printf(format)
 				}
+				close(reply)
+			case reply := <-resetC:
+				drainSink()
+				gologgers = []*Instance{newInstance("default", "[LEVEL] SUBJECT, MESSAGE", os.Stdout).initialize()}
+				level = Info
+				close(reply)
 			}
 		}
 	}()
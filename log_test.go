@@ -0,0 +1,81 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestSetLogLevelGetLogLevelAfterRegister exercises the exact pattern
+// AddLoggerInstance's and AddLoggerInstanceWithLevel's doc comments
+// recommend: call SetLogLevel/GetLogLevel immediately after registering.
+// Before levelRequest carried the *Instance directly, InstanceLevel could
+// be serviced before the still-pending RegisterLogger case, so the name
+// lookup failed and the set silently no-opped or the get returned All.
+func TestSetLogLevelGetLogLevelAfterRegister(t *testing.T) {
+	for i := 0; i < 500; i++ {
+		inst := AddLoggerInstance(fmt.Sprintf("race-%d", i), "[LEVEL] SUBJECT, MESSAGE", io.Discard)
+		inst.SetLogLevel(Error)
+		if got := inst.GetLogLevel(); got != Error {
+			t.Fatalf("iteration %d: GetLogLevel() = %v, want %v", i, got, Error)
+		}
+	}
+}
+
+// TestAddLoggerInstanceWithLevelGetLogLevel covers the AddLoggerInstanceWithLevel
+// variant of the same race: the level is set at registration time, so
+// GetLogLevel right after must see it even if InstanceLevel is serviced
+// before RegisterLogger.
+func TestAddLoggerInstanceWithLevelGetLogLevel(t *testing.T) {
+	for i := 0; i < 500; i++ {
+		inst := AddLoggerInstanceWithLevel(fmt.Sprintf("race-lvl-%d", i), "[LEVEL] SUBJECT, MESSAGE", io.Discard, Error)
+		if got := inst.GetLogLevel(); got != Error {
+			t.Fatalf("iteration %d: GetLogLevel() = %v, want %v", i, got, Error)
+		}
+	}
+}
+
+// syncBuffer pairs a bytes.Buffer with a syncer so Flush's "call Sync on
+// any writer that implements it" behavior can be observed.
+type syncBuffer struct {
+	bytes.Buffer
+	synced bool
+}
+
+func (s *syncBuffer) Sync() error {
+	s.synced = true
+	return nil
+}
+
+func TestFlushDrainsAndSyncs(t *testing.T) {
+	Reset()
+
+	buf := &syncBuffer{}
+	AddLoggerInstance("flush-test", "[LEVEL] SUBJECT, MESSAGE", buf)
+
+	Log(Info, "test", "hello %d", 1)
+	Flush()
+
+	if !strings.Contains(buf.String(), "hello 1") {
+		t.Fatalf("Flush did not drain the message before returning, got %q", buf.String())
+	}
+	if !buf.synced {
+		t.Fatal("Flush did not Sync the writer")
+	}
+
+	// Safe to call again with nothing queued.
+	Flush()
+}
+
+func TestResetClearsInstances(t *testing.T) {
+	Reset()
+
+	AddLoggerInstance("temp", "[LEVEL] SUBJECT, MESSAGE", io.Discard)
+	Reset()
+
+	if len(gologgers) != 1 || gologgers[0].Name != "default" {
+		t.Fatalf("Reset() left gologgers = %+v, want only the default instance", gologgers)
+	}
+}
@@ -0,0 +1,68 @@
+package golog
+
+import (
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Logger is a fluent accumulator of structured fields built with With. It
+// has no dispatcher of its own; every call forwards a Message to Sink just
+// like the package-level Log function, with Fields carried along.
+type Logger struct {
+	fields map[string]interface{}
+}
+
+// With starts a Logger carrying key/value, or, called on an existing
+// Logger, returns a child carrying the parent's fields plus this one.
+// Child scopes never mutate the parent's fields.
+func With(key string, value interface{}) *Logger {
+	return (&Logger{}).With(key, value)
+}
+
+func (lg *Logger) With(key string, value interface{}) *Logger {
+	fields := make(map[string]interface{}, len(lg.fields)+1)
+	for k, v := range lg.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+
+	return &Logger{fields: fields}
+}
+
+func (lg *Logger) log(level LogLevel, subject interface{}, format string, elements ...interface{}) {
+	pc, name, line, _ := runtime.Caller(2)
+	fun := runtime.FuncForPC(pc)
+	if fun != nil {
+		name = fun.Name()
+	}
+
+	values := map[string]string{
+		ExpandLineNumber:   strconv.Itoa(line),
+		ExpandFunctionName: name,
+		ExpandTime:         time.Now().String(),
+		ExpandDuration:     time.Now().Sub(start).String(),
+	}
+
+	Sink <- Message{level, subject, nil, values, format, elements, lg.fields}
+}
+
+func (lg *Logger) Trace(subject interface{}, format string, elements ...interface{}) {
+	lg.log(Trace, subject, format, elements...)
+}
+
+func (lg *Logger) Debug(subject interface{}, format string, elements ...interface{}) {
+	lg.log(Debug, subject, format, elements...)
+}
+
+func (lg *Logger) Info(subject interface{}, format string, elements ...interface{}) {
+	lg.log(Info, subject, format, elements...)
+}
+
+func (lg *Logger) Warning(subject interface{}, format string, elements ...interface{}) {
+	lg.log(Warning, subject, format, elements...)
+}
+
+func (lg *Logger) Error(subject interface{}, format string, elements ...interface{}) {
+	lg.log(Error, subject, format, elements...)
+}
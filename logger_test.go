@@ -0,0 +1,24 @@
+package golog
+
+import "testing"
+
+func TestLoggerWithAccumulatesAndDoesNotMutateParent(t *testing.T) {
+	parent := With("component", "auth")
+	child := parent.With("user_id", 42)
+
+	if len(parent.fields) != 1 {
+		t.Fatalf("parent.fields mutated by child With: %v", parent.fields)
+	}
+
+	if child.fields["component"] != "auth" || child.fields["user_id"] != 42 {
+		t.Fatalf("child missing accumulated fields: %v", child.fields)
+	}
+
+	grandchild := child.With("component", "db")
+	if child.fields["component"] != "auth" {
+		t.Fatalf("child.fields mutated by grandchild With: %v", child.fields)
+	}
+	if grandchild.fields["component"] != "db" || grandchild.fields["user_id"] != 42 {
+		t.Fatalf("grandchild missing overridden/inherited fields: %v", grandchild.fields)
+	}
+}
@@ -0,0 +1,53 @@
+package golog
+
+import "regexp"
+
+// Middleware inspects or mutates a Message before it is formatted for a
+// single Instance. Returning false drops the message for that instance.
+// Install with Instance.Use.
+type Middleware func(*Message) bool
+
+// RedactKeys returns a Middleware that replaces the value of any matching
+// Fields key with "***", e.g. RedactKeys("password", "token").
+func RedactKeys(keys ...string) Middleware {
+	redact := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		redact[k] = struct{}{}
+	}
+
+	return func(msg *Message) bool {
+		for k := range msg.Fields {
+			if _, ok := redact[k]; ok {
+				msg.Fields[k] = "***"
+			}
+		}
+
+		return true
+	}
+}
+
+// RedactRegexp returns a Middleware that replaces every match of re in the
+// expanded message with replacement.
+func RedactRegexp(re *regexp.Regexp, replacement string) Middleware {
+	return func(msg *Message) bool {
+		msg.values[ExpandMessage] = re.ReplaceAllString(msg.values[ExpandMessage], replacement)
+		return true
+	}
+}
+
+// Sample returns a Middleware that passes only 1 in every n messages, kept
+// per LogLevel, to bound the cost of hot Debug/Trace paths. n <= 1 passes
+// everything through.
+func Sample(n int) Middleware {
+	if n <= 1 {
+		return func(*Message) bool { return true }
+	}
+
+	counts := make(map[LogLevel]uint64)
+
+	return func(msg *Message) bool {
+		c := counts[msg.Level]
+		counts[msg.Level] = c + 1
+		return c%uint64(n) == 0
+	}
+}
@@ -0,0 +1,158 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestRedactKeysReplacesMatchingFieldsOnly(t *testing.T) {
+	mw := RedactKeys("password", "token")
+
+	msg := &Message{Fields: map[string]interface{}{
+		"password": "hunter2",
+		"token":    "abc123",
+		"user_id":  42,
+	}}
+
+	if !mw(msg) {
+		t.Fatal("RedactKeys dropped the message, it should never drop")
+	}
+
+	if msg.Fields["password"] != "***" || msg.Fields["token"] != "***" {
+		t.Fatalf("matching fields not redacted: %v", msg.Fields)
+	}
+	if msg.Fields["user_id"] != 42 {
+		t.Fatalf("unrelated field mutated: %v", msg.Fields)
+	}
+}
+
+func TestRedactRegexp(t *testing.T) {
+	re := regexp.MustCompile(`\d{4}-\d{4}-\d{4}-\d{4}`)
+	mw := RedactRegexp(re, "****-****-****-****")
+
+	msg := &Message{values: map[string]string{
+		ExpandMessage: "card 1234-5678-9012-3456 charged",
+	}}
+
+	if !mw(msg) {
+		t.Fatal("RedactRegexp dropped the message, it should never drop")
+	}
+
+	want := "card ****-****-****-**** charged"
+	if msg.values[ExpandMessage] != want {
+		t.Fatalf("message = %q, want %q", msg.values[ExpandMessage], want)
+	}
+}
+
+func TestSampleKeepsOneInN(t *testing.T) {
+	mw := Sample(3)
+
+	var kept int
+	for i := 0; i < 9; i++ {
+		if mw(&Message{Level: Info}) {
+			kept++
+		}
+	}
+
+	if kept != 3 {
+		t.Fatalf("kept %d of 9 messages, want 3 (1-in-3)", kept)
+	}
+}
+
+func TestSampleCountsPerLevel(t *testing.T) {
+	mw := Sample(2)
+
+	var infoKept, errorKept int
+	for i := 0; i < 4; i++ {
+		if mw(&Message{Level: Info}) {
+			infoKept++
+		}
+		if mw(&Message{Level: Error}) {
+			errorKept++
+		}
+	}
+
+	if infoKept != 2 || errorKept != 2 {
+		t.Fatalf("infoKept=%d errorKept=%d, want 2 and 2 (independent per-level counters)", infoKept, errorKept)
+	}
+}
+
+func TestSampleNPassesEverything(t *testing.T) {
+	mw := Sample(0)
+
+	for i := 0; i < 5; i++ {
+		if !mw(&Message{Level: Debug}) {
+			t.Fatalf("iteration %d: Sample(0) dropped a message, should pass everything", i)
+		}
+	}
+}
+
+// TestMiddlewareOrderRedactBeforeSample exercises the documented ordering
+// (redact before sample) through the real dispatcher: every message that
+// survives Sample must already be scrubbed, never a raw field value.
+func TestMiddlewareOrderRedactBeforeSample(t *testing.T) {
+	Reset()
+
+	var buf bytes.Buffer
+	inst := AddLoggerInstanceWithFormatter("order-test", "[LEVEL] SUBJECT, MESSAGE", &buf, JSONFormatter{})
+	inst.Use(RedactKeys("password"), Sample(2))
+	Flush()
+
+	for i := 0; i < 6; i++ {
+		With("password", "hunter2").Info("svc", "attempt %d", i)
+	}
+	Flush()
+
+	dec := json.NewDecoder(&buf)
+	var seen int
+	for dec.More() {
+		var entry map[string]interface{}
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("decode entry %d: %v", seen, err)
+		}
+		seen++
+		if entry["password"] != "***" {
+			t.Fatalf("entry %d: password = %v, want *** (redact must run before sample drops anything)", seen, entry["password"])
+		}
+	}
+
+	if seen != 3 {
+		t.Fatalf("saw %d entries through Sample(2) of 6 sent, want 3", seen)
+	}
+}
+
+// TestRedactKeysDoesNotLeakToInstancesWithoutMiddleware confirms RedactKeys
+// mutates only the per-instance clone dispatch makes for instances with a
+// middleware chain, never the shared Message seen by instances with none.
+func TestRedactKeysDoesNotLeakToInstancesWithoutMiddleware(t *testing.T) {
+	Reset()
+
+	var redacted, plain bytes.Buffer
+
+	withMW := AddLoggerInstanceWithFormatter("redact-test", "[LEVEL] SUBJECT, MESSAGE", &redacted, JSONFormatter{})
+	withMW.Use(RedactKeys("password"))
+	Flush()
+
+	AddLoggerInstanceWithFormatter("plain-test", "[LEVEL] SUBJECT, MESSAGE", &plain, JSONFormatter{})
+	Flush()
+
+	With("password", "hunter2").Info("svc", "login")
+	Flush()
+
+	var redactedEntry, plainEntry map[string]interface{}
+	if err := json.Unmarshal(redacted.Bytes(), &redactedEntry); err != nil {
+		t.Fatalf("invalid JSON from redacted instance: %v, out=%q", err, redacted.String())
+	}
+	if err := json.Unmarshal(plain.Bytes(), &plainEntry); err != nil {
+		t.Fatalf("invalid JSON from plain instance: %v, out=%q", err, plain.String())
+	}
+
+	if redactedEntry["password"] != "***" {
+		t.Fatalf("redacted instance password = %v, want ***", redactedEntry["password"])
+	}
+	if plainEntry["password"] != "hunter2" {
+		t.Fatalf("plain instance password = %v, want hunter2 (unaffected by the other instance's middleware)", plainEntry["password"])
+	}
+}
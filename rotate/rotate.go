@@ -0,0 +1,263 @@
+// Package rotate provides a rotating file io.Writer, so callers no longer
+// have to hand-roll one to pass as the output argument to
+// golog.AddLoggerInstance.
+package rotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RotatingFile is an io.Writer that rotates its underlying file by size
+// and/or age, keeps at most MaxBackups rotated copies, and can gzip them.
+// It is safe for concurrent use from multiple goroutines.
+type RotatingFile struct {
+	// Path is the file actively being written to.
+	Path string
+	// MaxSizeBytes rotates once a write would push the file past this
+	// size. Zero disables size-based rotation.
+	MaxSizeBytes int64
+	// MaxAge rotates the file once it has been open longer than this.
+	// Zero disables age-based rotation.
+	MaxAge time.Duration
+	// MaxBackups is how many rotated files to retain; the oldest beyond
+	// that are removed. Zero retains all of them.
+	MaxBackups int
+	// Compress gzips rotated files as they are created.
+	Compress bool
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	sighup   chan os.Signal
+}
+
+// New opens (creating if necessary) path for appending and returns a
+// ready to use RotatingFile.
+func New(path string, maxSizeBytes int64, maxAge time.Duration, maxBackups int, compress bool) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		Path:         path,
+		MaxSizeBytes: maxSizeBytes,
+		MaxAge:       maxAge,
+		MaxBackups:   maxBackups,
+		Compress:     compress,
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	rf.file = f
+	rf.size = info.Size()
+	rf.openedAt = time.Now()
+	return nil
+}
+
+// Write implements io.Writer, rotating first if this write would cross
+// MaxSizeBytes or the file has outlived MaxAge.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if rf.shouldRotate(int64(len(p))) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *RotatingFile) shouldRotate(nextWrite int64) bool {
+	if rf.MaxSizeBytes > 0 && rf.size+nextWrite > rf.MaxSizeBytes {
+		return true
+	}
+
+	if rf.MaxAge > 0 && time.Since(rf.openedAt) > rf.MaxAge {
+		return true
+	}
+
+	return false
+}
+
+// Rotate closes the current file, renames it aside (optionally gzipping
+// it), prunes backups beyond MaxBackups, and opens a fresh file at Path.
+// It can be called directly, e.g. wired up to SIGHUP via WatchSIGHUP.
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return rf.rotate()
+}
+
+func (rf *RotatingFile) rotate() error {
+	if rf.file != nil {
+		rf.file.Close()
+		rf.file = nil
+	}
+
+	if _, err := os.Stat(rf.Path); err == nil {
+		backup := rf.Path + "." + time.Now().Format("20060102T150405.000000000")
+		if err := os.Rename(rf.Path, backup); err != nil {
+			return err
+		}
+
+		if rf.Compress {
+			if err := gzipFile(backup); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := rf.prune(); err != nil {
+		return err
+	}
+
+	return rf.open()
+}
+
+// prune removes the oldest backups once there are more than MaxBackups.
+func (rf *RotatingFile) prune() error {
+	if rf.MaxBackups <= 0 {
+		return nil
+	}
+
+	matches, err := filepath.Glob(rf.Path + ".*")
+	if err != nil {
+		return err
+	}
+
+	if len(matches) <= rf.MaxBackups {
+		return nil
+	}
+
+	sort.Strings(matches)
+
+	for _, old := range matches[:len(matches)-rf.MaxBackups] {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(out)
+	if _, err := io.Copy(gw, in); err != nil {
+		gw.Close()
+		out.Close()
+		return err
+	}
+
+	if err := gw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// WatchSIGHUP starts a goroutine that calls Rotate whenever the process
+// receives SIGHUP, the logrotate convention for "reopen your log file".
+// Call Stop to release the signal handler.
+func (rf *RotatingFile) WatchSIGHUP() {
+	rf.sighup = make(chan os.Signal, 1)
+	signal.Notify(rf.sighup, syscall.SIGHUP)
+
+	go func() {
+		for range rf.sighup {
+			if err := rf.Rotate(); err != nil {
+				fmt.Fprintf(os.Stderr, "rotate: SIGHUP rotate of %s failed: %v\n", rf.Path, err)
+			}
+		}
+	}()
+}
+
+// Stop releases the SIGHUP handler installed by WatchSIGHUP. No-op if
+// WatchSIGHUP was never called.
+func (rf *RotatingFile) Stop() {
+	if rf.sighup == nil {
+		return
+	}
+
+	signal.Stop(rf.sighup)
+	close(rf.sighup)
+	rf.sighup = nil
+}
+
+// Sync flushes the current file to stable storage. It lets RotatingFile
+// satisfy the unexported syncer interface golog.Flush looks for.
+func (rf *RotatingFile) Sync() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return nil
+	}
+
+	return rf.file.Sync()
+}
+
+// Close closes the underlying file and stops any SIGHUP watch.
+func (rf *RotatingFile) Close() error {
+	rf.Stop()
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return nil
+	}
+
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
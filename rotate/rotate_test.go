@@ -0,0 +1,160 @@
+package rotate
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWriteRotatesPastMaxSizeAndPrunesBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := New(path, 10, 0, 2, false)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer rf.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := rf.Write([]byte("0123456789")); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("backups = %d, want 2 (MaxBackups), matches=%v", len(matches), matches)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat current file: %v", err)
+	}
+	if info.Size() != 10 {
+		t.Fatalf("current file size = %d, want 10 (only the last write since the latest rotation)", info.Size())
+	}
+}
+
+func TestWriteRotatesPastMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := New(path, 0, time.Millisecond, 0, false)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer rf.Close()
+
+	if _, err := rf.Write([]byte("first")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := rf.Write([]byte("second")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("backups = %v, want exactly one after a MaxAge rotation", matches)
+	}
+}
+
+func TestRotateCompressesBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := New(path, 0, 0, 0, true)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer rf.Close()
+
+	if _, err := rf.Write([]byte("hello")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := rf.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("backups = %v, want exactly one", matches)
+	}
+	if filepath.Ext(matches[0]) != ".gz" {
+		t.Fatalf("backup %s was not gzip-compressed", matches[0])
+	}
+}
+
+func TestSyncAndClose(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := New(path, 0, 0, 0, false)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := rf.Write([]byte("data")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := rf.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	if err := rf.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// golog.Flush calls Sync on every writer that implements it without
+	// knowing whether it has already been closed; that must not error.
+	if err := rf.Sync(); err != nil {
+		t.Fatalf("Sync after Close: %v", err)
+	}
+}
+
+func TestWatchSIGHUPTriggersRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := New(path, 0, 0, 0, false)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer rf.Close()
+
+	rf.WatchSIGHUP()
+	defer rf.Stop()
+
+	if _, err := rf.Write([]byte("before")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		matches, _ := filepath.Glob(path + ".*")
+		if len(matches) == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("SIGHUP did not trigger a rotation within the timeout")
+}
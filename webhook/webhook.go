@@ -0,0 +1,364 @@
+// Package webhook provides io.Writer sinks that batch log lines and post
+// them to Slack, Discord, or an arbitrary HTTP endpoint, so they can be
+// used as the output argument to golog.AddLoggerInstance.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configures how a Writer batches and sends log lines. A nil
+// Options, or zero fields within one, fall back to the defaults below.
+type Options struct {
+	// FlushInterval is how often buffered lines are sent even if
+	// MaxBatch hasn't been reached. Defaults to 5s.
+	FlushInterval time.Duration
+	// MaxBatch is the most lines held before a send is forced early.
+	// Defaults to 20.
+	MaxBatch int
+	// Client posts the batches. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+func (o *Options) withDefaults() Options {
+	out := Options{FlushInterval: 5 * time.Second, MaxBatch: 20, Client: http.DefaultClient}
+	if o == nil {
+		return out
+	}
+
+	if o.FlushInterval > 0 {
+		out.FlushInterval = o.FlushInterval
+	}
+	if o.MaxBatch > 0 {
+		out.MaxBatch = o.MaxBatch
+	}
+	if o.Client != nil {
+		out.Client = o.Client
+	}
+
+	return out
+}
+
+// levelMeta is the emoji and accent color shown for a line whose level we
+// recognised. color is a "#rrggbb" hex string: Slack attachments take it
+// as-is, Discord embeds want it as a decimal int (see hexToDecimal).
+type levelMeta struct {
+	emoji string
+	color string
+}
+
+// neutralMeta is used for lines whose level we couldn't recognise.
+var neutralMeta = levelMeta{color: "#95a5a6"}
+
+// levelPrefixes matches the LEVEL token golog.LogLevel.String() produces
+// with golog's default "[LEVEL] SUBJECT, MESSAGE" Format.
+var levelPrefixes = []struct {
+	prefix string
+	meta   levelMeta
+}{
+	{"ERR ", levelMeta{":red_circle:", "#e01e5a"}},
+	{"WARN", levelMeta{":warning:", "#ecb22e"}},
+	{"INFO", levelMeta{":information_source:", "#2eb67d"}},
+	{"DBG ", levelMeta{":bug:", "#36c5f0"}},
+	{"TRC ", levelMeta{":mag:", "#868686"}},
+}
+
+// metaFor returns the levelMeta for a line, falling back to neutralMeta
+// when none of levelPrefixes matches.
+func metaFor(line string) levelMeta {
+	for _, l := range levelPrefixes {
+		if strings.Contains(line, l.prefix) {
+			return l.meta
+		}
+	}
+
+	return neutralMeta
+}
+
+func decorate(lines []string) string {
+	decorated := make([]string, len(lines))
+	for i, line := range lines {
+		meta := metaFor(line)
+		if meta.emoji == "" {
+			decorated[i] = line
+			continue
+		}
+
+		decorated[i] = meta.emoji + " " + line
+	}
+
+	return strings.Join(decorated, "\n")
+}
+
+// levelGroup is a run of consecutive lines sharing the same levelMeta, so
+// a colored attachment/embed can be emitted per run instead of per line.
+type levelGroup struct {
+	meta  levelMeta
+	lines []string
+}
+
+// groupByLevel splits lines into consecutive runs of the same recognised
+// level, preserving order.
+func groupByLevel(lines []string) []levelGroup {
+	var groups []levelGroup
+	for _, line := range lines {
+		meta := metaFor(line)
+		if n := len(groups); n > 0 && groups[n-1].meta == meta {
+			groups[n-1].lines = append(groups[n-1].lines, line)
+			continue
+		}
+
+		groups = append(groups, levelGroup{meta: meta, lines: []string{line}})
+	}
+
+	return groups
+}
+
+// hexToDecimal converts a "#rrggbb" color into the decimal form Discord's
+// embed "color" field expects. An unparseable color comes back as 0.
+func hexToDecimal(hex string) int {
+	n, err := strconv.ParseInt(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil {
+		return 0
+	}
+
+	return int(n)
+}
+
+// Writer batches lines written to it and periodically POSTs them to a
+// webhook URL. Build one with NewSlackWriter, NewDiscordWriter, or
+// NewHTTPWriter. Close flushes anything buffered and stops the timer.
+type Writer struct {
+	url  string
+	opts Options
+	send func(w *Writer, lines []string) error
+
+	mu   sync.Mutex
+	buf  []string
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newWriter(url string, opts *Options, send func(*Writer, []string) error) *Writer {
+	w := &Writer{
+		url:  url,
+		opts: opts.withDefaults(),
+		send: send,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go w.loop()
+	return w
+}
+
+func (w *Writer) loop() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.Flush()
+		case <-w.stop:
+			w.Flush()
+			return
+		}
+	}
+}
+
+// Write implements io.Writer, appending p as a single buffered line and
+// forcing an early send once MaxBatch is reached.
+func (w *Writer) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p), "\n")
+
+	w.mu.Lock()
+	w.buf = append(w.buf, line)
+	full := len(w.buf) >= w.opts.MaxBatch
+	w.mu.Unlock()
+
+	if full {
+		w.Flush()
+	}
+
+	return len(p), nil
+}
+
+// Flush sends any buffered lines immediately, ignoring the flush timer.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	lines := w.buf
+	w.buf = nil
+	w.mu.Unlock()
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return w.send(w, lines)
+}
+
+// Close flushes any buffered lines and stops the background flush timer.
+func (w *Writer) Close() error {
+	close(w.stop)
+	<-w.done
+	return nil
+}
+
+// Sync flushes any buffered lines. It lets Writer satisfy the unexported
+// syncer interface golog.Flush looks for, so a batch that hasn't hit
+// FlushInterval or MaxBatch yet still goes out before shutdown.
+func (w *Writer) Sync() error {
+	return w.Flush()
+}
+
+func (w *Writer) post(contentType string, body []byte) error {
+	resp, err := w.opts.Client.Post(w.url, contentType, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: %s returned status %d", w.url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// slackAttachment is a single colored block in a Slack message, one per
+// run of same-level lines.
+type slackAttachment struct {
+	Color string `json:"color"`
+	Text  string `json:"text"`
+}
+
+// NewSlackWriter posts each batch to a Slack incoming webhook URL as a set
+// of attachments, one per run of consecutive same-level lines, each
+// carrying that level's accent color and with a per-level emoji prefixed
+// onto recognised lines.
+func NewSlackWriter(url string, opts *Options) *Writer {
+	return newWriter(url, opts, func(w *Writer, lines []string) error {
+		groups := groupByLevel(lines)
+		attachments := make([]slackAttachment, len(groups))
+		for i, g := range groups {
+			attachments[i] = slackAttachment{Color: g.meta.color, Text: decorate(g.lines)}
+		}
+
+		body, err := json.Marshal(map[string]interface{}{"attachments": attachments})
+		if err != nil {
+			return err
+		}
+
+		return w.post("application/json", body)
+	})
+}
+
+// discordMaxContent is Discord's limit on a single embed's "description"
+// field that we choose to honor here (Discord itself allows up to 4096).
+const discordMaxContent = 2000
+
+// discordMaxEmbeds is Discord's limit on embeds per message.
+const discordMaxEmbeds = 10
+
+// discordEmbed is a single colored block in a Discord message, one per
+// chunk of a same-level run of lines.
+type discordEmbed struct {
+	Description string `json:"description"`
+	Color       int    `json:"color"`
+}
+
+// NewDiscordWriter posts each batch to a Discord webhook URL as embeds,
+// one per chunk of a run of consecutive same-level lines, each carrying
+// that level's accent color, splitting on line boundaries whenever a run
+// would exceed Discord's content limit, and posting in batches of at most
+// discordMaxEmbeds embeds per message.
+func NewDiscordWriter(url string, opts *Options) *Writer {
+	return newWriter(url, opts, func(w *Writer, lines []string) error {
+		var embeds []discordEmbed
+		for _, g := range groupByLevel(lines) {
+			color := hexToDecimal(g.meta.color)
+			for _, chunk := range splitChunks(decorate(g.lines), discordMaxContent) {
+				embeds = append(embeds, discordEmbed{Description: chunk, Color: color})
+			}
+		}
+
+		for len(embeds) > 0 {
+			n := discordMaxEmbeds
+			if n > len(embeds) {
+				n = len(embeds)
+			}
+
+			body, err := json.Marshal(map[string]interface{}{"embeds": embeds[:n]})
+			if err != nil {
+				return err
+			}
+
+			if err := w.post("application/json", body); err != nil {
+				return err
+			}
+
+			embeds = embeds[n:]
+		}
+
+		return nil
+	})
+}
+
+// splitChunks breaks s into pieces of at most max characters, preferring
+// to break on line boundaries and hard-splitting any single line that is
+// itself longer than max.
+func splitChunks(s string, max int) []string {
+	if len(s) <= max {
+		return []string{s}
+	}
+
+	var chunks []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(s, "\n") {
+		for len(line) > max {
+			if current.Len() > 0 {
+				chunks = append(chunks, current.String())
+				current.Reset()
+			}
+			chunks = append(chunks, line[:max])
+			line = line[max:]
+		}
+
+		if current.Len() > 0 && current.Len()+len(line)+1 > max {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+
+		if current.Len() > 0 {
+			current.WriteByte('\n')
+		}
+		current.WriteString(line)
+	}
+
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	return chunks
+}
+
+// NewHTTPWriter posts each batch to url using template as a fmt.Sprintf
+// format string with a single verb (such as %s or %q), filled in with the batch's
+// decorated lines joined by newlines, e.g. `{"text": %q}`.
+func NewHTTPWriter(url, template string, opts *Options) *Writer {
+	return newWriter(url, opts, func(w *Writer, lines []string) error {
+		body := fmt.Sprintf(template, decorate(lines))
+		return w.post("application/json", []byte(body))
+	})
+}